@@ -17,19 +17,31 @@ limitations under the License.
 package alpha
 
 import (
+	"context"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"sort"
+	"os"
+	"path/filepath"
 	"strings"
+	"text/template"
 
+	"github.com/lithammer/dedent"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/jsonpath"
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
 	"k8s.io/kubernetes/cmd/kubeadm/app/cmd/options"
 	cmdutil "k8s.io/kubernetes/cmd/kubeadm/app/cmd/util"
 	"k8s.io/kubernetes/cmd/kubeadm/app/componentconfigs"
 	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/upgrade/compatcheck"
 	"k8s.io/kubernetes/cmd/kubeadm/app/util/config"
 	"k8s.io/kubernetes/cmd/kubeadm/app/util/kubeconfig"
 )
@@ -50,6 +62,8 @@ func newCmdAlphaConfig(out io.Writer) *cobra.Command {
 	kubeConfigFile = cmdutil.GetKubeConfigPath(kubeConfigFile)
 
 	cmd.AddCommand(newCmdAlphaConfigPrint(out, &kubeConfigFile))
+	cmd.AddCommand(newCmdAlphaConfigMigrate(out, &kubeConfigFile))
+	cmd.AddCommand(newCmdAlphaConfigValidate(out, &kubeConfigFile))
 
 	return cmd
 }
@@ -69,6 +83,9 @@ func newCmdAlphaConfigPrint(out io.Writer, kubeConfigFile *string) *cobra.Comman
 
 // newCmdAlphaConfigPrintUpgradeable handles the `kubeadm alpha config print upgradeable` command
 func newCmdAlphaConfigPrintUpgradeable(out io.Writer, kubeConfigPath *string) *cobra.Command {
+	var output string
+	var diff bool
+
 	cmd := &cobra.Command{
 		Use:   "upgradeable",
 		Short: "Print component configs that need manual upgrading",
@@ -79,41 +96,298 @@ func newCmdAlphaConfigPrintUpgradeable(out io.Writer, kubeConfigPath *string) *c
 				return err
 			}
 
-			// Fetch only the kubeadm configuration from the cluster. Don't fetch the component configs
-			// Also, mute this func as it can output a few messages, but we want to keep the output clean for the YAML
-			cfg, err := config.FetchInitConfigurationFromCluster(client, ioutil.Discard, "", false, true)
+			configs, err := compatcheck.ListUpgradeable(client)
 			if err != nil {
 				return err
 			}
 
-			// Get a DocumentMap with the unsupported component configs
-			docmap, err := componentconfigs.FetchUnsupportedConfigsFromCluster(&cfg.ClusterConfiguration, client)
+			if diff {
+				return printUpgradeableDiff(out, configs)
+			}
+
+			return printUpgradeable(out, configs, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format. One of: yaml|json|jsonpath=...|go-template=...")
+	cmd.Flags().BoolVar(&diff, "diff", false, "Show a unified diff between the current unsupported YAML and the currently supported version, suitable for `kubectl apply --patch`")
+
+	return cmd
+}
+
+// printUpgradeable renders configs using format
+func printUpgradeable(out io.Writer, configs []compatcheck.UpgradeableConfig, format string) error {
+	switch {
+	case format == "yaml":
+		return compatcheck.RenderUpgradeable(out, configs, compatcheck.FormatYAML)
+	case format == "json":
+		return compatcheck.RenderUpgradeable(out, configs, compatcheck.FormatJSON)
+	case strings.HasPrefix(format, "jsonpath="):
+		return printWithJSONPath(out, strings.TrimPrefix(format, "jsonpath="), configs)
+	case strings.HasPrefix(format, "go-template="):
+		return printWithGoTemplate(out, strings.TrimPrefix(format, "go-template="), configs)
+	default:
+		return errors.Errorf("unsupported output format %q; must be one of yaml, json, jsonpath=..., go-template=...", format)
+	}
+}
+
+// printUpgradeableDiff renders, for each config, a unified diff between the current unsupported
+// YAML and the result of converting it to the currently supported version
+func printUpgradeableDiff(out io.Writer, configs []compatcheck.UpgradeableConfig) error {
+	for _, c := range configs {
+		udiff := difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(c.CurrentYAML)),
+			B:        difflib.SplitLines(string(c.UpgradedYAML)),
+			FromFile: c.GVK.String(),
+			ToFile:   c.SupportedGVK.String(),
+			Context:  3,
+		}
+		text, err := difflib.GetUnifiedDiffString(udiff)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't diff %s", c.GVK)
+		}
+		fmt.Fprint(out, text)
+	}
+
+	return nil
+}
+
+// printWithJSONPath renders data using a kubectl-style JSONPath expression
+func printWithJSONPath(out io.Writer, expression string, data interface{}) error {
+	jp := jsonpath.New("upgradeable")
+	if err := jp.Parse(expression); err != nil {
+		return errors.Wrapf(err, "invalid jsonpath expression %q", expression)
+	}
+	return jp.Execute(out, data)
+}
+
+// printWithGoTemplate renders data using a kubectl-style Go template
+func printWithGoTemplate(out io.Writer, tmplText string, data interface{}) error {
+	tmpl, err := template.New("upgradeable").Parse(tmplText)
+	if err != nil {
+		return errors.Wrapf(err, "invalid go-template expression %q", tmplText)
+	}
+	return tmpl.Execute(out, data)
+}
+
+// newCmdAlphaConfigMigrate is a placeholder for alpha commands that would eventually graduate
+// into the main `kubeadm config migrate` command
+func newCmdAlphaConfigMigrate(out io.Writer, kubeConfigFile *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate configuration",
+	}
+
+	cmd.AddCommand(newCmdAlphaConfigMigrateUpgradeable(out, kubeConfigFile))
+
+	return cmd
+}
+
+// newCmdAlphaConfigMigrateUpgradeable handles the `kubeadm alpha config migrate upgradeable` command
+func newCmdAlphaConfigMigrateUpgradeable(out io.Writer, kubeConfigPath *string) *cobra.Command {
+	var dryRun bool
+	var backupDir string
+	var confirm bool
+
+	cmd := &cobra.Command{
+		Use:   "upgradeable",
+		Short: "Migrate component configs that need manual upgrading to the currently supported version",
+		Long: dedent.Dedent(`
+			Fetches the component configs that compatcheck.ListUpgradeable reports as unsupported,
+			converts each of them to the version the currently running kubeadm supports, and writes
+			the result back to the cluster ConfigMap it was read from. Use --dry-run to perform the
+			conversion without mutating the cluster, and --backup to save the pre-migration YAML of
+			every GVK before it is touched.
+		`),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			// First, obtain a clientset from the kubeconfig file
+			client, err := kubeconfig.ClientSetFromFile(*kubeConfigPath)
 			if err != nil {
 				return err
 			}
 
-			// We need to make sure, that our output is predictable, but the maps in Go are unordered.
-			// Hence, we have to extract and sort the map keys.
-			gvks := make([]schema.GroupVersionKind, 0, len(docmap))
-			for gvk := range docmap {
-				gvks = append(gvks, gvk)
+			configs, err := compatcheck.ListUpgradeable(client)
+			if err != nil {
+				return err
 			}
-			sort.Slice(gvks, func(i, j int) bool {
-				return gvks[i].String() < gvks[j].String()
-			})
 
-			// Finally, use the sorted keys to fetch the unsupported YAML and print it
-			for _, gvk := range gvks {
-				// Don't forget the YAML document separator. It has a trailing '\n' char, so we use just Fprint here
-				fmt.Fprint(out, constants.YAMLDocumentSeparator)
+			if len(configs) == 0 {
+				fmt.Fprintln(out, "[config-migrate] All component configs are already using a supported version, nothing to do")
+				return nil
+			}
 
-				// Output the YAML document, while making sure that we don't have any spurious leading and/or trailing spaces
-				fmt.Fprintln(out, strings.TrimSpace(string(docmap[gvk])))
+			if backupDir != "" {
+				if err := backupUpgradeableConfigs(backupDir, configs); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "[config-migrate] Wrote pre-migration YAML for %d GVKs to %s\n", len(configs), backupDir)
+			}
+
+			for _, c := range configs {
+				if confirm {
+					msg := fmt.Sprintf("migrate %s to %s", c.GVK, c.SupportedGVK)
+					if err := cmdutil.InteractivelyConfirmAction(msg, fmt.Sprintf("Are you sure you want to %s?", msg), os.Stdin); err != nil {
+						fmt.Fprintf(out, "[config-migrate] Skipping %s: %v\n", c.GVK, err)
+						continue
+					}
+				}
+
+				if err := applyMigratedConfig(client, c.GVK, c.UpgradedYAML, dryRun); err != nil {
+					return errors.Wrapf(err, "couldn't apply migrated %s", c.GVK)
+				}
+
+				verb := "Migrated"
+				if dryRun {
+					verb = "Would migrate"
+				}
+				fmt.Fprintf(out, "[config-migrate] %s %s to %s\n", verb, c.GVK, c.SupportedGVK)
 			}
 
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Don't mutate the cluster; use the API server's server-side dry-run instead")
+	cmd.Flags().StringVar(&backupDir, "backup", "", "Directory to save the pre-migration YAML of every affected GVK to, before it's mutated")
+	cmd.Flags().BoolVar(&confirm, "confirm", false, "Interactively confirm each GVK before migrating it")
+
+	return cmd
+}
+
+// backupUpgradeableConfigs writes the pre-migration YAML of every config to dir, one file per GVK
+func backupUpgradeableConfigs(dir string, configs []compatcheck.UpgradeableConfig) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrapf(err, "couldn't create backup directory %s", dir)
+	}
+
+	for _, c := range configs {
+		fileName := strings.ReplaceAll(c.GVK.String(), "/", "_") + ".yaml"
+		path := filepath.Join(dir, fileName)
+		if err := os.WriteFile(path, c.CurrentYAML, 0600); err != nil {
+			return errors.Wrapf(err, "couldn't back up %s to %s", c.GVK, path)
+		}
+	}
+
+	return nil
+}
+
+// applyMigratedConfig writes the migrated ConfigMap back to the cluster, optionally using server-side dry-run
+func applyMigratedConfig(client kubernetes.Interface, gvk schema.GroupVersionKind, yaml []byte, dryRun bool) error {
+	namespace, name, dataKey, ok := componentconfigs.ConfigMapForGVK(gvk)
+	if !ok {
+		return errors.Errorf("don't know which ConfigMap backs %s", gvk)
+	}
+
+	cm, err := client.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	cm.Data[dataKey] = string(yaml)
+
+	// Keep the hash kubeadm uses for drift detection in sync with the data key we just wrote,
+	// the same way the rest of kubeadm does whenever it rewrites a component config ConfigMap.
+	if cm.Annotations == nil {
+		cm.Annotations = map[string]string{}
+	}
+	cm.Annotations[constants.ComponentConfigHashKey] = componentConfigHash(yaml)
+
+	opts := metav1.UpdateOptions{}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	_, err = client.CoreV1().ConfigMaps(namespace).Update(context.TODO(), cm, opts)
+	return err
+}
+
+// componentConfigHash returns the drift-detection hash kubeadm stores alongside a component
+// config ConfigMap whenever it writes one
+func componentConfigHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("sha256:%x", sum)
+}
+
+// newCmdAlphaConfigValidate handles the `kubeadm alpha config validate` command
+func newCmdAlphaConfigValidate(out io.Writer, kubeConfigPath *string) *cobra.Command {
+	var cfgPath string
+	var fromCluster bool
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate a kubeadm config against the schemas of its embedded component configs",
+		Long: dedent.Dedent(`
+			Parses every embedded component config in the given document (the kubeadm
+			Init/Join/Cluster configuration, KubeletConfiguration and KubeProxyConfiguration)
+			as YAML, and reports every GVK that is unknown or not at the currently supported
+			version, plus any field within an otherwise-supported document that is deprecated
+			or has been removed in the currently supported version. Documents aren't decoded
+			through a registered scheme, and no defaulting is applied.
+			Exits non-zero if any finding has error severity, so it can gate CI.
+		`),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if cfgPath == "" && !fromCluster {
+				return errors.New("one of --config or --from-cluster is required")
+			}
+
+			var docmap kubeadmapi.DocumentMap
+
+			if fromCluster {
+				client, err := kubeconfig.ClientSetFromFile(*kubeConfigPath)
+				if err != nil {
+					return err
+				}
+
+				cfg, err := config.FetchInitConfigurationFromCluster(client, ioutil.Discard, "", false, true)
+				if err != nil {
+					return err
+				}
+
+				docmap, err = componentconfigs.FetchAllConfigsFromCluster(&cfg.ClusterConfiguration, client)
+				if err != nil {
+					return err
+				}
+			} else {
+				data, err := ioutil.ReadFile(cfgPath)
+				if err != nil {
+					return errors.Wrapf(err, "couldn't read config file %s", cfgPath)
+				}
+				docmap, err = config.SplitYAMLDocuments(data)
+				if err != nil {
+					return errors.Wrapf(err, "couldn't parse config file %s", cfgPath)
+				}
+			}
+
+			diagnostics, err := compatcheck.ValidateDocumentMap(docmap)
+			if err != nil {
+				return err
+			}
+
+			switch output {
+			case "yaml":
+				if err := compatcheck.RenderDiagnostics(out, diagnostics, compatcheck.FormatYAML); err != nil {
+					return err
+				}
+			case "json":
+				if err := compatcheck.RenderDiagnostics(out, diagnostics, compatcheck.FormatJSON); err != nil {
+					return err
+				}
+			default:
+				return errors.Errorf("unsupported output format %q; must be one of yaml, json", output)
+			}
+
+			if compatcheck.HasErrors(diagnostics) {
+				return errors.New("one or more component configs failed validation")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&cfgPath, "config", "", "Path to a kubeadm config file to validate")
+	cmd.Flags().BoolVar(&fromCluster, "from-cluster", false, "Validate the component configs currently stored in the cluster instead of a local file")
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format. One of: yaml|json")
+
 	return cmd
 }
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewCmdAlphaConfigWiring(t *testing.T) {
+	cmd := newCmdAlphaConfig(&bytes.Buffer{})
+
+	wantCommands := []string{"print", "migrate", "validate"}
+	for _, name := range wantCommands {
+		if sub, _, err := cmd.Find([]string{name}); err != nil || sub.Name() != name {
+			t.Errorf("expected %q to be registered as a subcommand of `alpha config`", name)
+		}
+	}
+
+	print, _, err := cmd.Find([]string{"print", "upgradeable"})
+	if err != nil {
+		t.Fatalf("expected `print upgradeable` to be registered: %v", err)
+	}
+	for _, flag := range []string{"output", "diff"} {
+		if print.Flags().Lookup(flag) == nil {
+			t.Errorf("expected `print upgradeable` to have a %q flag", flag)
+		}
+	}
+
+	migrate, _, err := cmd.Find([]string{"migrate", "upgradeable"})
+	if err != nil {
+		t.Fatalf("expected `migrate upgradeable` to be registered: %v", err)
+	}
+	for _, flag := range []string{"dry-run", "backup", "confirm"} {
+		if migrate.Flags().Lookup(flag) == nil {
+			t.Errorf("expected `migrate upgradeable` to have a %q flag", flag)
+		}
+	}
+
+	validate, _, err := cmd.Find([]string{"validate"})
+	if err != nil {
+		t.Fatalf("expected `validate` to be registered: %v", err)
+	}
+	for _, flag := range []string{"config", "from-cluster", "output"} {
+		if validate.Flags().Lookup(flag) == nil {
+			t.Errorf("expected `validate` to have a %q flag", flag)
+		}
+	}
+}
+
+func TestPrintUpgradeableUnsupportedFormat(t *testing.T) {
+	if err := printUpgradeable(&bytes.Buffer{}, nil, "xml"); err == nil {
+		t.Errorf("expected an error for an unsupported output format")
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/kubernetes/cmd/kubeadm/app/phases/upgrade/compatcheck"
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/kubeconfig"
+)
+
+// newCmdPlan returns the cobra command for `kubeadm upgrade plan`
+func newCmdPlan(out io.Writer) *cobra.Command {
+	var kubeConfigPath string
+
+	cmd := &cobra.Command{
+		Use:   "plan [version]",
+		Short: "Warn about component configs the cluster runs with an unsupported version; does not list available versions",
+		RunE: func(_ *cobra.Command, args []string) error {
+			client, err := kubeconfig.ClientSetFromFile(kubeConfigPath)
+			if err != nil {
+				return err
+			}
+
+			// Surface any component configs the cluster is still running with an unsupported
+			// version. This is all the command does: upgrade targets aren't listed, and the
+			// [version] argument isn't used.
+			return compatcheck.PrintUpgradeableWarnings(out, client)
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeConfigPath, "kubeconfig", kubeConfigPath, "The kubeconfig file to use when talking to the cluster")
+
+	return cmd
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// NewCmdUpgrade returns the cobra command for `kubeadm upgrade`
+func NewCmdUpgrade(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade your cluster smoothly to a newer version with this command",
+	}
+
+	cmd.AddCommand(newCmdPlan(out))
+	cmd.AddCommand(newCmdApply(out))
+
+	return cmd
+}
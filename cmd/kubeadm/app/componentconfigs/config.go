@@ -0,0 +1,223 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package componentconfigs
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+// registration describes, for a single component config kind, where the cluster stores its
+// ConfigMap, and which GroupVersionKind kubeadm currently considers supported for that kind
+type registration struct {
+	// namespace and name identify the ConfigMap the component reads its config from
+	namespace, name string
+	// dataKey is the key within the ConfigMap's Data the component config YAML is stored under
+	dataKey string
+	// supportedGVK is the GroupVersionKind kubeadm currently supports for this kind
+	supportedGVK schema.GroupVersionKind
+}
+
+// registrations maps each known component config Kind to its registration
+var registrations = map[string]registration{
+	"KubeletConfiguration": {
+		namespace: metav1.NamespaceSystem,
+		name:      "kubelet-config",
+		dataKey:   "kubelet",
+		supportedGVK: schema.GroupVersionKind{
+			Group:   "kubelet.config.k8s.io",
+			Version: "v1beta1",
+			Kind:    "KubeletConfiguration",
+		},
+	},
+	"KubeProxyConfiguration": {
+		namespace: metav1.NamespaceSystem,
+		name:      "kube-proxy",
+		dataKey:   "config.conf",
+		supportedGVK: schema.GroupVersionKind{
+			Group:   "kubeproxy.config.k8s.io",
+			Version: "v1alpha1",
+			Kind:    "KubeProxyConfiguration",
+		},
+	},
+}
+
+// ConfigMapForGVK returns the namespace, name and ConfigMap data key that backs gvk's Kind,
+// regardless of which version of it the cluster happens to be running. ok is false if gvk's
+// Kind isn't a component config kubeadm knows how to locate.
+func ConfigMapForGVK(gvk schema.GroupVersionKind) (namespace, name, dataKey string, ok bool) {
+	reg, known := registrations[gvk.Kind]
+	if !known {
+		return "", "", "", false
+	}
+	return reg.namespace, reg.name, reg.dataKey, true
+}
+
+// ConvertToSupportedVersion converts data, a document of the given gvk, to the
+// GroupVersionKind kubeadm currently supports for that Kind. If gvk is already the supported
+// version, data is returned unchanged. Otherwise, besides rewriting apiVersion/kind, it moves
+// every field removedFieldsByKind records as relocated (e.g. KubeletConfiguration's
+// podPidsLimit to kubeReserved.podPidsLimit) to its new location, and drops fields that were
+// removed outright, so the result is actually usable by the currently supported version
+// instead of just wearing its apiVersion.
+func ConvertToSupportedVersion(gvk schema.GroupVersionKind, data []byte) ([]byte, schema.GroupVersionKind, error) {
+	reg, known := registrations[gvk.Kind]
+	if !known {
+		return nil, schema.GroupVersionKind{}, errors.Errorf("%s is not a component config kubeadm knows how to convert", gvk)
+	}
+
+	if gvk == reg.supportedGVK {
+		return data, gvk, nil
+	}
+
+	converted, err := migrateDocument(data, reg.supportedGVK)
+	if err != nil {
+		return nil, schema.GroupVersionKind{}, errors.Wrapf(err, "couldn't convert %s to %s", gvk, reg.supportedGVK)
+	}
+
+	return converted, reg.supportedGVK, nil
+}
+
+// migrateDocument rewrites data's apiVersion/kind to target, and relocates or drops every
+// field removedFieldsByKind records for target.Kind
+func migrateDocument(data []byte, target schema.GroupVersionKind) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "couldn't unmarshal document")
+	}
+
+	doc["apiVersion"] = target.GroupVersion().String()
+	doc["kind"] = target.Kind
+
+	for _, field := range removedFieldsByKind[target.Kind] {
+		migrateField(doc, field.path, field.replacement)
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't marshal converted document")
+	}
+
+	return out, nil
+}
+
+// migrateField moves the value at oldPath to newPath within doc, removing oldPath. If newPath
+// is empty, or oldPath isn't present, oldPath is simply dropped (the field was either removed
+// outright, or was never set to begin with).
+func migrateField(doc map[string]interface{}, oldPath, newPath string) {
+	value, ok := popField(doc, oldPath)
+	if !ok || newPath == "" {
+		return
+	}
+	setField(doc, newPath, value)
+}
+
+// popField removes and returns the value at the dotted path (e.g. ".podPidsLimit") within doc
+func popField(doc map[string]interface{}, path string) (interface{}, bool) {
+	segments := splitPath(path)
+	if len(segments) == 0 {
+		return nil, false
+	}
+
+	cur := doc
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := cur[segment].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur = next
+	}
+
+	last := segments[len(segments)-1]
+	value, ok := cur[last]
+	if !ok {
+		return nil, false
+	}
+	delete(cur, last)
+	return value, true
+}
+
+// setField sets value at the dotted path (e.g. ".kubeReserved.podPidsLimit") within doc,
+// creating any intermediate maps that don't already exist
+func setField(doc map[string]interface{}, path string, value interface{}) {
+	segments := splitPath(path)
+	if len(segments) == 0 {
+		return
+	}
+
+	cur := doc
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := cur[segment].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			cur[segment] = next
+		}
+		cur = next
+	}
+
+	cur[segments[len(segments)-1]] = value
+}
+
+// FetchAllConfigsFromCluster returns a DocumentMap containing every component config the
+// cluster is currently running, regardless of whether kubeadm still considers its version
+// supported. Unlike FetchUnsupportedConfigsFromCluster, callers get every known kind back and
+// are expected to classify each document themselves, e.g. via ValidateDocument.
+func FetchAllConfigsFromCluster(clusterCfg *kubeadmapi.ClusterConfiguration, client kubernetes.Interface) (kubeadmapi.DocumentMap, error) {
+	docmap := kubeadmapi.DocumentMap{}
+
+	for kind, reg := range registrations {
+		cm, err := client.CoreV1().ConfigMaps(reg.namespace).Get(context.TODO(), reg.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't fetch the ConfigMap backing %s", kind)
+		}
+
+		data, ok := cm.Data[reg.dataKey]
+		if !ok {
+			continue
+		}
+
+		gvk, err := gvkFromDocument([]byte(data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't determine the GroupVersionKind of the %s ConfigMap", kind)
+		}
+
+		docmap[gvk] = []byte(data)
+	}
+
+	return docmap, nil
+}
+
+// gvkFromDocument reads the apiVersion/kind of a single YAML document
+func gvkFromDocument(data []byte) (schema.GroupVersionKind, error) {
+	var typeMeta metav1.TypeMeta
+	if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	return typeMeta.GroupVersionKind(), nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package componentconfigs
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+func TestConfigMapForGVK(t *testing.T) {
+	namespace, name, dataKey, ok := ConfigMapForGVK(schema.GroupVersionKind{
+		Group:   "kubeproxy.config.k8s.io",
+		Version: "v1alpha1",
+		Kind:    "KubeProxyConfiguration",
+	})
+	if !ok {
+		t.Fatalf("expected KubeProxyConfiguration to be known")
+	}
+	if namespace != "kube-system" || name != "kube-proxy" || dataKey != "config.conf" {
+		t.Errorf("got namespace=%s name=%s dataKey=%s, want namespace=kube-system name=kube-proxy dataKey=config.conf", namespace, name, dataKey)
+	}
+
+	if _, _, _, ok := ConfigMapForGVK(schema.GroupVersionKind{Kind: "NotAThing"}); ok {
+		t.Errorf("expected an unknown kind to report ok=false")
+	}
+}
+
+func TestConvertToSupportedVersionNoop(t *testing.T) {
+	supported := schema.GroupVersionKind{Group: "kubelet.config.k8s.io", Version: "v1beta1", Kind: "KubeletConfiguration"}
+	data := []byte("apiVersion: kubelet.config.k8s.io/v1beta1\nkind: KubeletConfiguration\n")
+
+	converted, gvk, err := ConvertToSupportedVersion(supported, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk != supported {
+		t.Errorf("got %s, want %s", gvk, supported)
+	}
+	if string(converted) != string(data) {
+		t.Errorf("expected data to be returned unchanged for the already-supported version")
+	}
+}
+
+func TestConvertToSupportedVersionRewritesTypeMeta(t *testing.T) {
+	old := schema.GroupVersionKind{Group: "kubeproxy.config.k8s.io", Version: "v1alpha2", Kind: "KubeProxyConfiguration"}
+	data := []byte("apiVersion: kubeproxy.config.k8s.io/v1alpha2\nkind: KubeProxyConfiguration\nmode: iptables\n")
+
+	converted, gvk, err := ConvertToSupportedVersion(old, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk.Version != "v1alpha1" {
+		t.Errorf("got version %s, want v1alpha1", gvk.Version)
+	}
+	if !strings.Contains(string(converted), "mode: iptables") {
+		t.Errorf("expected unrelated fields to survive conversion, got %q", converted)
+	}
+}
+
+func TestConvertToSupportedVersionUnknownKind(t *testing.T) {
+	if _, _, err := ConvertToSupportedVersion(schema.GroupVersionKind{Kind: "NotAThing"}, nil); err == nil {
+		t.Errorf("expected an error for an unknown kind")
+	}
+}
+
+func TestConvertToSupportedVersionMigratesRelocatedField(t *testing.T) {
+	old := schema.GroupVersionKind{Group: "kubelet.config.k8s.io", Version: "v1alpha1", Kind: "KubeletConfiguration"}
+	data := []byte("apiVersion: kubelet.config.k8s.io/v1alpha1\nkind: KubeletConfiguration\npodPidsLimit: 100\n")
+
+	converted, _, err := ConvertToSupportedVersion(old, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(converted, &doc); err != nil {
+		t.Fatalf("couldn't unmarshal converted document: %v", err)
+	}
+
+	if _, ok := doc["podPidsLimit"]; ok {
+		t.Errorf("expected the top-level podPidsLimit to be migrated away, got %v", doc)
+	}
+
+	kubeReserved, ok := doc["kubeReserved"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected kubeReserved to be populated, got %v", doc)
+	}
+	if kubeReserved["podPidsLimit"] != float64(100) {
+		t.Errorf("got kubeReserved.podPidsLimit=%v, want 100", kubeReserved["podPidsLimit"])
+	}
+}
+
+func TestConvertToSupportedVersionDropsRemovedField(t *testing.T) {
+	old := schema.GroupVersionKind{Group: "kubeproxy.config.k8s.io", Version: "v1alpha2", Kind: "KubeProxyConfiguration"}
+	data := []byte("apiVersion: kubeproxy.config.k8s.io/v1alpha2\nkind: KubeProxyConfiguration\nudpIdleTimeout: 1s\nmode: iptables\n")
+
+	converted, _, err := ConvertToSupportedVersion(old, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(converted, &doc); err != nil {
+		t.Fatalf("couldn't unmarshal converted document: %v", err)
+	}
+	if _, ok := doc["udpIdleTimeout"]; ok {
+		t.Errorf("expected the removed udpIdleTimeout field to be dropped, got %v", doc)
+	}
+	if doc["mode"] != "iptables" {
+		t.Errorf("expected unrelated fields to survive conversion, got %v", doc)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package componentconfigs
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// FieldDiagnostic is a single per-field finding produced by ValidateDocument
+type FieldDiagnostic struct {
+	// Path is the field path within the document, e.g. ".cgroupDriver", or "." for findings
+	// that apply to the whole document, such as an unsupported GVK
+	Path string
+	// Severity is either "error" or "warning"
+	Severity string
+	// Message is a human-readable description of the finding
+	Message string
+	// Replacement is the suggested replacement for the offending field or GVK, if any
+	Replacement string
+}
+
+// removedField describes a field that used to exist on a component config Kind but was
+// deprecated, or removed, by the time of the currently supported version
+type removedField struct {
+	path        string
+	severity    string
+	message     string
+	replacement string
+}
+
+// kubeadmConfigVersions maps each of kubeadm's own config Kinds (as opposed to a component
+// config backed by a ConfigMap, such as KubeletConfiguration) to the GroupVersionKind kubeadm
+// currently supports for it. ValidateDocument special-cases these, since they're not in
+// registrations: ConfigMapForGVK/FetchAllConfigsFromCluster have nothing to do with them, they
+// only matter for per-GVK version validation.
+var kubeadmConfigVersions = map[string]schema.GroupVersionKind{
+	"InitConfiguration": {
+		Group:   "kubeadm.k8s.io",
+		Version: "v1beta2",
+		Kind:    "InitConfiguration",
+	},
+	"ClusterConfiguration": {
+		Group:   "kubeadm.k8s.io",
+		Version: "v1beta2",
+		Kind:    "ClusterConfiguration",
+	},
+	"JoinConfiguration": {
+		Group:   "kubeadm.k8s.io",
+		Version: "v1beta2",
+		Kind:    "JoinConfiguration",
+	},
+}
+
+// removedFieldsByKind lists, for each component config Kind, the fields that ValidateDocument
+// flags when they're still present in an otherwise-supported document
+var removedFieldsByKind = map[string][]removedField{
+	"KubeletConfiguration": {
+		{
+			path:        ".podPidsLimit",
+			severity:    "warning",
+			message:     "podPidsLimit is deprecated in favor of kubeReserved.podPidsLimit",
+			replacement: ".kubeReserved.podPidsLimit",
+		},
+	},
+	"KubeProxyConfiguration": {
+		{
+			path:     ".udpIdleTimeout",
+			severity: "warning",
+			message:  "udpIdleTimeout has no effect since the userspace proxy mode was removed",
+		},
+	},
+}
+
+// ValidateDocument reports any GVK that's unknown or no longer supported, plus any field listed
+// in removedFieldsByKind that's still present in a document already at the currently supported
+// version. data is parsed as plain YAML after ConvertToSupportedVersion; it isn't decoded
+// through a registered scheme, and no defaulting is applied. For a document at an older version,
+// the conversion relocates or drops those fields first, so only the version error is reported.
+func ValidateDocument(gvk schema.GroupVersionKind, data []byte) ([]FieldDiagnostic, error) {
+	if supportedGVK, known := kubeadmConfigVersions[gvk.Kind]; known {
+		return validateKubeadmConfigVersion(gvk, supportedGVK), nil
+	}
+
+	reg, known := registrations[gvk.Kind]
+	if !known {
+		return []FieldDiagnostic{{
+			Path:     ".",
+			Severity: "error",
+			Message:  fmt.Sprintf("%s is not a recognized component config kind", gvk.Kind),
+		}}, nil
+	}
+
+	var diagnostics []FieldDiagnostic
+	if gvk != reg.supportedGVK {
+		diagnostics = append(diagnostics, FieldDiagnostic{
+			Path:        ".",
+			Severity:    "error",
+			Message:     fmt.Sprintf("%s is not the currently supported version", gvk.GroupVersion()),
+			Replacement: reg.supportedGVK.String(),
+		})
+	}
+
+	// Run the document through the same conversion the migrate command uses, so field-level
+	// findings below are computed against the shape the currently supported version expects.
+	converted, _, err := ConvertToSupportedVersion(gvk, data)
+	if err != nil {
+		diagnostics = append(diagnostics, FieldDiagnostic{
+			Path:     ".",
+			Severity: "error",
+			Message:  err.Error(),
+		})
+		return diagnostics, nil
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(converted, &doc); err != nil {
+		return nil, errors.Wrap(err, "couldn't decode document for structural validation")
+	}
+
+	for _, field := range removedFieldsByKind[gvk.Kind] {
+		if fieldExists(doc, field.path) {
+			diagnostics = append(diagnostics, FieldDiagnostic{
+				Path:        field.path,
+				Severity:    field.severity,
+				Message:     field.message,
+				Replacement: field.replacement,
+			})
+		}
+	}
+
+	return diagnostics, nil
+}
+
+// validateKubeadmConfigVersion reports whether gvk is the GroupVersionKind kubeadm currently
+// supports for a kubeadm config Kind (InitConfiguration, ClusterConfiguration,
+// JoinConfiguration). Unlike component configs, kubeadm defaults and converts its own config
+// kinds on every read, so there's no per-field deprecation table to check here, only the GVK
+// itself.
+func validateKubeadmConfigVersion(gvk, supportedGVK schema.GroupVersionKind) []FieldDiagnostic {
+	if gvk == supportedGVK {
+		return nil
+	}
+
+	return []FieldDiagnostic{{
+		Path:        ".",
+		Severity:    "error",
+		Message:     fmt.Sprintf("%s is not the currently supported version", gvk.GroupVersion()),
+		Replacement: supportedGVK.String(),
+	}}
+}
+
+// fieldExists reports whether the dotted path (e.g. ".kubeReserved.podPidsLimit") is present
+// and non-nil in doc
+func fieldExists(doc map[string]interface{}, path string) bool {
+	segments := splitPath(path)
+	if len(segments) == 0 {
+		return false
+	}
+
+	cur := doc
+	for i, segment := range segments {
+		value, ok := cur[segment]
+		if !ok || value == nil {
+			return false
+		}
+
+		if i == len(segments)-1 {
+			return true
+		}
+
+		next, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		cur = next
+	}
+
+	return false
+}
+
+// splitPath turns ".a.b.c" into ["a", "b", "c"]
+func splitPath(path string) []string {
+	var segments []string
+	var current []rune
+	for _, r := range path {
+		if r == '.' {
+			if len(current) > 0 {
+				segments = append(segments, string(current))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		segments = append(segments, string(current))
+	}
+	return segments
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package componentconfigs
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestValidateDocumentUnknownKind(t *testing.T) {
+	diags, err := ValidateDocument(schema.GroupVersionKind{Kind: "NotAThing"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Severity != "error" {
+		t.Fatalf("expected a single error diagnostic for an unknown kind, got %+v", diags)
+	}
+}
+
+func TestValidateDocumentUnsupportedVersion(t *testing.T) {
+	old := schema.GroupVersionKind{Group: "kubeproxy.config.k8s.io", Version: "v1alpha2", Kind: "KubeProxyConfiguration"}
+	data := []byte("apiVersion: kubeproxy.config.k8s.io/v1alpha2\nkind: KubeProxyConfiguration\n")
+
+	diags, err := ValidateDocument(old, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var found bool
+	for _, d := range diags {
+		if d.Path == "." && d.Severity == "error" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a whole-document error diagnostic for the unsupported version, got %+v", diags)
+	}
+}
+
+func TestValidateDocumentRemovedField(t *testing.T) {
+	supported := schema.GroupVersionKind{Group: "kubelet.config.k8s.io", Version: "v1beta1", Kind: "KubeletConfiguration"}
+	data := []byte("apiVersion: kubelet.config.k8s.io/v1beta1\nkind: KubeletConfiguration\npodPidsLimit: 100\n")
+
+	diags, err := ValidateDocument(supported, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var found bool
+	for _, d := range diags {
+		if d.Path == ".podPidsLimit" {
+			found = true
+			if d.Replacement != ".kubeReserved.podPidsLimit" {
+				t.Errorf("got replacement %q, want .kubeReserved.podPidsLimit", d.Replacement)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected a diagnostic for the deprecated podPidsLimit field, got %+v", diags)
+	}
+}
+
+func TestValidateDocumentKubeadmConfigKinds(t *testing.T) {
+	for _, kind := range []string{"InitConfiguration", "ClusterConfiguration", "JoinConfiguration"} {
+		supported := schema.GroupVersionKind{Group: "kubeadm.k8s.io", Version: "v1beta2", Kind: kind}
+
+		diags, err := ValidateDocument(supported, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kind, err)
+		}
+		if len(diags) != 0 {
+			t.Errorf("%s: expected a document at the supported version to validate cleanly, got %+v", kind, diags)
+		}
+
+		old := schema.GroupVersionKind{Group: "kubeadm.k8s.io", Version: "v1beta1", Kind: kind}
+		diags, err = ValidateDocument(old, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kind, err)
+		}
+		if len(diags) != 1 || diags[0].Severity != "error" {
+			t.Errorf("%s: expected an unsupported-version document to report one error, got %+v", kind, diags)
+		}
+	}
+}
+
+func TestFieldExists(t *testing.T) {
+	doc := map[string]interface{}{
+		"kubeReserved": map[string]interface{}{
+			"podPidsLimit": 100,
+		},
+	}
+
+	if !fieldExists(doc, ".kubeReserved.podPidsLimit") {
+		t.Errorf("expected .kubeReserved.podPidsLimit to exist")
+	}
+	if fieldExists(doc, ".kubeReserved.cpu") {
+		t.Errorf("expected .kubeReserved.cpu to not exist")
+	}
+	if fieldExists(doc, ".unknown.path") {
+		t.Errorf("expected .unknown.path to not exist")
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compatcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/cmd/kubeadm/app/componentconfigs"
+	"k8s.io/kubernetes/cmd/kubeadm/app/constants"
+	"k8s.io/kubernetes/cmd/kubeadm/app/util/config"
+)
+
+// Format is an output format supported by RenderUpgradeable
+type Format string
+
+const (
+	// FormatYAML renders the current, unsupported YAML of every UpgradeableConfig
+	FormatYAML Format = "yaml"
+	// FormatJSON renders a structured report of every UpgradeableConfig
+	FormatJSON Format = "json"
+)
+
+// UpgradeableConfig describes a single component config document that the cluster is
+// currently running with a GroupVersionKind kubeadm no longer considers supported
+type UpgradeableConfig struct {
+	// GVK is the GroupVersionKind the cluster is currently running
+	GVK schema.GroupVersionKind
+	// SupportedGVK is the GroupVersionKind that GVK should be converted to
+	SupportedGVK schema.GroupVersionKind
+	// CurrentYAML is the document as it's currently stored in the cluster
+	CurrentYAML []byte
+	// UpgradedYAML is CurrentYAML converted to SupportedGVK
+	UpgradedYAML []byte
+}
+
+// jsonReport is the structured representation of an UpgradeableConfig used by FormatJSON
+type jsonReport struct {
+	GVK              string `json:"gvk"`
+	CurrentVersion   string `json:"currentVersion"`
+	SupportedVersion string `json:"supportedVersion"`
+	Reason           string `json:"reason"`
+	YAML             string `json:"yaml"`
+}
+
+// ListUpgradeable fetches the kubeadm ClusterConfiguration from the cluster, uses it to find
+// the component configs that componentconfigs.FetchUnsupportedConfigsFromCluster reports as
+// unsupported, and converts each of them to the currently supported version. The result is
+// sorted by GVK, so callers get a predictable, diffable ordering.
+func ListUpgradeable(client kubernetes.Interface) ([]UpgradeableConfig, error) {
+	// Fetch only the kubeadm configuration from the cluster. Don't fetch the component configs.
+	// Also, mute this func as it can output a few messages, but callers may want clean output.
+	cfg, err := config.FetchInitConfigurationFromCluster(client, ioutil.Discard, "", false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get a DocumentMap with the unsupported component configs
+	docmap, err := componentconfigs.FetchUnsupportedConfigsFromCluster(&cfg.ClusterConfiguration, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// We need to make sure, that our output is predictable, but the maps in Go are unordered.
+	// Hence, we have to extract and sort the map keys.
+	gvks := make([]schema.GroupVersionKind, 0, len(docmap))
+	for gvk := range docmap {
+		gvks = append(gvks, gvk)
+	}
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+
+	configs := make([]UpgradeableConfig, 0, len(gvks))
+	for _, gvk := range gvks {
+		current := docmap[gvk]
+		upgraded, supportedGVK, err := componentconfigs.ConvertToSupportedVersion(gvk, current)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't convert %s to a supported version", gvk)
+		}
+
+		configs = append(configs, UpgradeableConfig{
+			GVK:          gvk,
+			SupportedGVK: supportedGVK,
+			CurrentYAML:  current,
+			UpgradedYAML: upgraded,
+		})
+	}
+
+	return configs, nil
+}
+
+// PrintUpgradeableWarnings lists the component configs the cluster is currently running that
+// kubeadm no longer considers supported, and prints one warning line per GVK to out. It's
+// called by `kubeadm upgrade plan` and `kubeadm upgrade apply`, so an unsupported component
+// config is reported instead of being silently ignored. It returns nil, without printing anything, if every
+// component config is already on a supported version.
+func PrintUpgradeableWarnings(out io.Writer, client kubernetes.Interface) error {
+	configs, err := ListUpgradeable(client)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range configs {
+		fmt.Fprintf(out, "[upgrade/config] FYI: the %s config is not the currently supported version (%s); run `kubeadm alpha config migrate upgradeable` to upgrade it\n", c.GVK, c.SupportedGVK)
+	}
+
+	return nil
+}
+
+// RenderUpgradeable writes configs to w using format
+func RenderUpgradeable(w io.Writer, configs []UpgradeableConfig, format Format) error {
+	switch format {
+	case FormatYAML:
+		for _, c := range configs {
+			// Don't forget the YAML document separator. It has a trailing '\n' char, so we use just Fprint here
+			fmt.Fprint(w, constants.YAMLDocumentSeparator)
+
+			// Output the YAML document, while making sure that we don't have any spurious leading and/or trailing spaces
+			fmt.Fprintln(w, strings.TrimSpace(string(c.CurrentYAML)))
+		}
+		return nil
+	case FormatJSON:
+		reports := make([]jsonReport, 0, len(configs))
+		for _, c := range configs {
+			reports = append(reports, jsonReport{
+				GVK:              c.GVK.String(),
+				CurrentVersion:   c.GVK.GroupVersion().String(),
+				SupportedVersion: c.SupportedGVK.GroupVersion().String(),
+				Reason:           fmt.Sprintf("%s is not the currently supported version (%s)", c.GVK.GroupVersion(), c.SupportedGVK),
+				YAML:             strings.TrimSpace(string(c.CurrentYAML)),
+			})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	default:
+		return errors.Errorf("unsupported format %q, must be one of %q, %q", format, FormatYAML, FormatJSON)
+	}
+}
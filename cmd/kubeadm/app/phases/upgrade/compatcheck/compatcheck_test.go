@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compatcheck
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func testConfig() []UpgradeableConfig {
+	return []UpgradeableConfig{
+		{
+			GVK:          schema.GroupVersionKind{Group: "kubeproxy.config.k8s.io", Version: "v1alpha2", Kind: "KubeProxyConfiguration"},
+			SupportedGVK: schema.GroupVersionKind{Group: "kubeproxy.config.k8s.io", Version: "v1alpha1", Kind: "KubeProxyConfiguration"},
+			CurrentYAML:  []byte("apiVersion: kubeproxy.config.k8s.io/v1alpha2\nkind: KubeProxyConfiguration\n"),
+			UpgradedYAML: []byte("apiVersion: kubeproxy.config.k8s.io/v1alpha1\nkind: KubeProxyConfiguration\n"),
+		},
+	}
+}
+
+func TestRenderUpgradeableYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderUpgradeable(&buf, testConfig(), FormatYAML); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "KubeProxyConfiguration") {
+		t.Errorf("expected output to contain the current YAML, got %q", buf.String())
+	}
+}
+
+func TestRenderUpgradeableJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderUpgradeable(&buf, testConfig(), FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"currentVersion"`) {
+		t.Errorf("expected a structured report, got %q", buf.String())
+	}
+}
+
+func TestRenderUpgradeableUnsupportedFormat(t *testing.T) {
+	if err := RenderUpgradeable(&bytes.Buffer{}, testConfig(), Format("xml")); err == nil {
+		t.Errorf("expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,25 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compatcheck exposes the "list the component configs a cluster is running that
+// kubeadm no longer considers supported" check as a library, so it's shared between
+// `kubeadm alpha config print/migrate upgradeable`, `kubeadm upgrade plan` and
+// `kubeadm upgrade apply`, instead of living inline in one command's RunE.
+//
+// `kubeadm upgrade plan` and `kubeadm upgrade apply` call PrintUpgradeableWarnings, so that an
+// unsupported component config is reported instead of being silently ignored. Printing these
+// warnings is all either command currently does.
+package compatcheck
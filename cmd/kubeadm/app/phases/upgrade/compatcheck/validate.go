@@ -0,0 +1,171 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compatcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+	"k8s.io/kubernetes/cmd/kubeadm/app/componentconfigs"
+)
+
+// Severity classifies how serious a Diagnostic is
+type Severity string
+
+const (
+	// SeverityError means the document couldn't be decoded, is of an unknown kind, or isn't at
+	// the currently supported version
+	SeverityError Severity = "error"
+	// SeverityWarning means the document is at a supported version, but still sets a deprecated
+	// or removed field
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a single per-field finding produced by ValidateDocumentMap
+type Diagnostic struct {
+	// GVK is the document the finding belongs to
+	GVK schema.GroupVersionKind
+	// Path is the field path within the document, e.g. ".cgroupDriver" or the document root (".")
+	// for whole-document findings such as an unknown or deprecated GVK
+	Path string
+	// Severity classifies how serious the finding is
+	Severity Severity
+	// Message is a human-readable description of the finding
+	Message string
+	// Replacement is the suggested replacement for the offending field or GVK, if any
+	Replacement string
+}
+
+// diagnosticReport is the structured representation of a Diagnostic used by FormatJSON
+type diagnosticReport struct {
+	GVK         string `json:"gvk"`
+	Path        string `json:"path"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// ValidateDocumentMap checks that every document in docmap is well-formed YAML and passes it
+// to componentconfigs.ValidateDocument, reporting any GVK that is unknown or not at the
+// currently supported version, and any field within an otherwise-supported document that is
+// deprecated or has been removed in the currently supported version. Documents aren't decoded
+// through a registered scheme, and no defaulting is applied.
+func ValidateDocumentMap(docmap kubeadmapi.DocumentMap) ([]Diagnostic, error) {
+	gvks := make([]schema.GroupVersionKind, 0, len(docmap))
+	for gvk := range docmap {
+		gvks = append(gvks, gvk)
+	}
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+
+	var diagnostics []Diagnostic
+	for _, gvk := range gvks {
+		data := docmap[gvk]
+
+		// A structural decode pass: make sure the document is at least well-formed YAML before
+		// handing it to the component-config-specific checks below. GVK-specific version and
+		// field-level diagnostics live in componentconfigs, which owns the table of component
+		// config kinds and their supported versions.
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			diagnostics = append(diagnostics, Diagnostic{
+				GVK:      gvk,
+				Path:     ".",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("couldn't decode document: %v", err),
+			})
+			continue
+		}
+
+		diags, err := componentconfigs.ValidateDocument(gvk, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't validate %s", gvk)
+		}
+		for _, d := range diags {
+			diagnostics = append(diagnostics, Diagnostic{
+				GVK:         gvk,
+				Path:        d.Path,
+				Severity:    Severity(d.Severity),
+				Message:     d.Message,
+				Replacement: d.Replacement,
+			})
+		}
+	}
+
+	return diagnostics, nil
+}
+
+// RenderDiagnostics writes diagnostics to w using format
+func RenderDiagnostics(w io.Writer, diagnostics []Diagnostic, format Format) error {
+	switch format {
+	case FormatYAML:
+		// Go through the real YAML marshaler instead of hand-formatting lines: a Message or
+		// Replacement containing a colon (which the wrapped decode-error messages this package
+		// produces routinely do) would otherwise break the output it produces.
+		reports := make([]diagnosticReport, 0, len(diagnostics))
+		for _, d := range diagnostics {
+			reports = append(reports, diagnosticReport{
+				GVK:         d.GVK.String(),
+				Path:        d.Path,
+				Severity:    string(d.Severity),
+				Message:     d.Message,
+				Replacement: d.Replacement,
+			})
+		}
+		out, err := yaml.Marshal(reports)
+		if err != nil {
+			return errors.Wrap(err, "couldn't marshal diagnostics")
+		}
+		_, err = w.Write(out)
+		return err
+	case FormatJSON:
+		reports := make([]diagnosticReport, 0, len(diagnostics))
+		for _, d := range diagnostics {
+			reports = append(reports, diagnosticReport{
+				GVK:         d.GVK.String(),
+				Path:        d.Path,
+				Severity:    string(d.Severity),
+				Message:     d.Message,
+				Replacement: d.Replacement,
+			})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	default:
+		return errors.Errorf("unsupported format %q, must be one of %q, %q", format, FormatYAML, FormatJSON)
+	}
+}
+
+// HasErrors reports whether diagnostics contains at least one SeverityError finding
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
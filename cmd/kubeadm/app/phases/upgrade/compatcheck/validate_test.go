@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compatcheck
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+func TestValidateDocumentMapDecodeError(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "kubelet.config.k8s.io", Version: "v1beta1", Kind: "KubeletConfiguration"}
+	docmap := kubeadmapi.DocumentMap{
+		gvk: []byte("not: valid: yaml: ["),
+	}
+
+	diagnostics, err := ValidateDocumentMap(docmap)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Severity != SeverityError {
+		t.Fatalf("expected a single error diagnostic for invalid YAML, got %+v", diagnostics)
+	}
+}
+
+func TestValidateDocumentMapSortsByGVK(t *testing.T) {
+	docmap := kubeadmapi.DocumentMap{
+		{Group: "kubeproxy.config.k8s.io", Version: "v1alpha1", Kind: "KubeProxyConfiguration"}: []byte("apiVersion: kubeproxy.config.k8s.io/v1alpha1\nkind: KubeProxyConfiguration\nudpIdleTimeout: 1s\n"),
+		{Group: "kubelet.config.k8s.io", Version: "v1beta1", Kind: "KubeletConfiguration"}:      []byte("apiVersion: kubelet.config.k8s.io/v1beta1\nkind: KubeletConfiguration\npodPidsLimit: 100\n"),
+	}
+
+	diagnostics, err := ValidateDocumentMap(docmap)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := RenderDiagnostics(&buf, diagnostics, FormatYAML); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kubeletIdx := strings.Index(buf.String(), "KubeletConfiguration")
+	proxyIdx := strings.Index(buf.String(), "KubeProxyConfiguration")
+	if kubeletIdx == -1 || proxyIdx == -1 || kubeletIdx > proxyIdx {
+		t.Errorf("expected KubeletConfiguration to sort before KubeProxyConfiguration, got %q", buf.String())
+	}
+}
+
+func TestRenderDiagnosticsYAMLRoundTripsColonInMessage(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "kubelet.config.k8s.io", Version: "v1beta1", Kind: "KubeletConfiguration"}
+	docmap := kubeadmapi.DocumentMap{
+		gvk: []byte("not: valid: yaml: ["),
+	}
+
+	diagnostics, err := ValidateDocumentMap(docmap)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected a single diagnostic, got %+v", diagnostics)
+	}
+	if !strings.Contains(diagnostics[0].Message, ":") {
+		t.Fatalf("expected the decode-error message to contain a colon, got %q", diagnostics[0].Message)
+	}
+
+	var buf bytes.Buffer
+	if err := RenderDiagnostics(&buf, diagnostics, FormatYAML); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var reports []diagnosticReport
+	if err := yaml.Unmarshal(buf.Bytes(), &reports); err != nil {
+		t.Fatalf("rendered YAML didn't round-trip: %v\noutput:\n%s", err, buf.String())
+	}
+	if len(reports) != 1 || reports[0].Message != diagnostics[0].Message {
+		t.Errorf("got reports %+v, want a single report with message %q", reports, diagnostics[0].Message)
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	if HasErrors(nil) {
+		t.Errorf("expected no errors for an empty diagnostic list")
+	}
+	if !HasErrors([]Diagnostic{{Severity: SeverityError}}) {
+		t.Errorf("expected HasErrors to report true when a SeverityError diagnostic is present")
+	}
+	if HasErrors([]Diagnostic{{Severity: SeverityWarning}}) {
+		t.Errorf("expected HasErrors to report false when only warnings are present")
+	}
+}